@@ -3,9 +3,10 @@ package database
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"os"
 	"strconv"
+	"time"
 
 	"log/slog"
 
@@ -19,7 +20,8 @@ import (
 )
 
 type Catalog struct {
-	db *bun.DB
+	db      *bun.DB
+	dialect Dialect
 
 	fixedPizzas  int
 	fixedUsers   int
@@ -27,15 +29,29 @@ type Catalog struct {
 	maxPizzas    int
 	maxUsers     int
 	maxRatings   int
+
+	// flushSignal wakes RunFlushLoop up early; see enforceTableSizeLimitsSoft
+	// and signalFlush.
+	flushSignal chan struct{}
 }
 
 func NewCatalog(connString string) (*Catalog, error) {
+	dialect, err := dialectForDSN(connString)
+	if err != nil {
+		return nil, err
+	}
+
 	db, err := initializeDB(connString)
 	if err != nil {
 		return nil, err
 	}
-	log := slog.With("db", "catalog")
-	mig := migrate.NewMigrator(db, migrations.Catalog)
+	log := slog.With("db", "catalog", "dialect", dialect.Name())
+
+	migSet, err := migrations.For(dialect.Name())
+	if err != nil {
+		return nil, err
+	}
+	mig := migrate.NewMigrator(db, migSet)
 	if err := mig.Init(context.Background()); err != nil {
 		return nil, err
 	}
@@ -46,15 +62,19 @@ func NewCatalog(connString string) (*Catalog, error) {
 		return nil, err
 	}
 	db.RegisterModel((*model.PizzaToIngredients)(nil))
+	db.RegisterModel((*model.UserToken)(nil))
+	db.AddQueryHook(tenantAuditHook{})
 
 	c := &Catalog{
 		db:           db,
+		dialect:      dialect,
 		fixedPizzas:  envInt("QUICKPIZZA_DB_FIXED_PIZZAS", 100),
 		fixedUsers:   envInt("QUICKPIZZA_DB_FIXED_USERS", 10),
 		fixedRatings: envInt("QUICKPIZZA_DB_FIXED_RATINGS", 10),
 		maxPizzas:    envInt("QUICKPIZZA_DB_MAX_PIZZAS", 5000),
 		maxUsers:     envInt("QUICKPIZZA_DB_MAX_USERS", 5000),
 		maxRatings:   envInt("QUICKPIZZA_DB_MAX_RATINGS", 10000),
+		flushSignal:  make(chan struct{}, 1),
 	}
 
 	log.Info(
@@ -67,10 +87,18 @@ func NewCatalog(connString string) (*Catalog, error) {
 		"maxRatings", c.maxRatings,
 	)
 
+	flushInterval := envDuration("QUICKPIZZA_DB_FLUSH_INTERVAL", 30*time.Second)
+	log.Info("starting background flush loop", "interval", flushInterval)
+	go c.RunFlushLoop(context.Background(), flushInterval)
+
 	return c, nil
 }
 
 func (c *Catalog) GetIngredients(ctx context.Context, t string) ([]model.Ingredient, error) {
+	if err := requireGlobalTable(ctx, "ingredients"); err != nil {
+		return nil, err
+	}
+
 	// Inject an artificial error for testing purposes
 	err := errorinjector.InjectErrors(ctx, "get-ingredients")
 	if err != nil {
@@ -83,6 +111,10 @@ func (c *Catalog) GetIngredients(ctx context.Context, t string) ([]model.Ingredi
 }
 
 func (c *Catalog) GetDoughs(ctx context.Context) ([]model.Dough, error) {
+	if err := requireGlobalTable(ctx, "doughs"); err != nil {
+		return nil, err
+	}
+
 	var doughs []model.Dough
 	err := c.db.NewSelect().Model(&doughs).Scan(ctx)
 	return doughs, err
@@ -95,12 +127,90 @@ func (c *Catalog) GetTools(ctx context.Context) ([]string, error) {
 }
 
 func (c *Catalog) GetHistory(ctx context.Context, limit int) ([]model.Pizza, error) {
+	if err := requireGlobalTable(ctx, "pizzas"); err != nil {
+		return nil, err
+	}
+
 	var history []model.Pizza
 	err := c.db.NewSelect().Model(&history).Relation("Dough").Relation("Ingredients").Order("created_at DESC").Limit(limit).Scan(ctx)
 	return history, err
 }
 
+// pizzaIngredientRow is the destination of the batched ingredient lookup in
+// GetHistoryBatched: one row per (pizza, ingredient) pair, joined through
+// PizzaToIngredients.
+type pizzaIngredientRow struct {
+	model.Ingredient
+	PizzaID int64 `bun:"pizza_id"`
+}
+
+// GetHistoryBatched is equivalent to GetHistory, but avoids the N+1 queries
+// that Relation("Dough")/Relation("Ingredients") issue for large limits: it
+// fetches the page of pizzas in one query, then exactly two follow-up
+// queries (doughs by ID, ingredients joined through PizzaToIngredients by
+// pizza ID), and stitches the results together here.
+func (c *Catalog) GetHistoryBatched(ctx context.Context, limit int) ([]model.Pizza, error) {
+	if err := requireGlobalTable(ctx, "pizzas"); err != nil {
+		return nil, err
+	}
+
+	var history []model.Pizza
+	if err := c.db.NewSelect().Model(&history).Order("created_at DESC").Limit(limit).Scan(ctx); err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return history, nil
+	}
+
+	pizzaIDs := make([]int64, len(history))
+	doughIDSet := make(map[int64]struct{}, len(history))
+	for i, p := range history {
+		pizzaIDs[i] = p.ID
+		doughIDSet[p.DoughID] = struct{}{}
+	}
+	doughIDs := make([]int64, 0, len(doughIDSet))
+	for id := range doughIDSet {
+		doughIDs = append(doughIDs, id)
+	}
+
+	var doughs []model.Dough
+	if err := c.db.NewSelect().Model(&doughs).Where("id IN (?)", bun.In(doughIDs)).Scan(ctx); err != nil {
+		return nil, err
+	}
+	doughByID := make(map[int64]model.Dough, len(doughs))
+	for _, d := range doughs {
+		doughByID[d.ID] = d
+	}
+
+	var ingredientRows []pizzaIngredientRow
+	err := c.db.NewSelect().
+		Model((*model.PizzaToIngredients)(nil)).
+		ColumnExpr("pizza_to_ingredients.pizza_id AS pizza_id").
+		ColumnExpr("ingredient.*").
+		Join("JOIN ingredients AS ingredient ON ingredient.id = pizza_to_ingredients.ingredient_id").
+		Where("pizza_to_ingredients.pizza_id IN (?)", bun.In(pizzaIDs)).
+		Scan(ctx, &ingredientRows)
+	if err != nil {
+		return nil, err
+	}
+	ingredientsByPizza := make(map[int64][]model.Ingredient, len(history))
+	for _, row := range ingredientRows {
+		ingredientsByPizza[row.PizzaID] = append(ingredientsByPizza[row.PizzaID], row.Ingredient)
+	}
+
+	for i := range history {
+		history[i].Dough = doughByID[history[i].DoughID]
+		history[i].Ingredients = ingredientsByPizza[history[i].ID]
+	}
+
+	return history, nil
+}
+
 func (c *Catalog) GetRecommendation(ctx context.Context, id int) (*model.Pizza, error) {
+	if err := requireGlobalTable(ctx, "pizzas"); err != nil {
+		return nil, err
+	}
+
 	var pizza model.Pizza
 	err := c.db.NewSelect().Model(&pizza).Relation("Dough").Relation("Ingredients").Where("pizza.id = ?", id).Limit(1).Scan(ctx)
 	if err == sql.ErrNoRows {
@@ -110,6 +220,10 @@ func (c *Catalog) GetRecommendation(ctx context.Context, id int) (*model.Pizza,
 }
 
 func (c *Catalog) RecordUser(ctx context.Context, user *model.User) error {
+	if err := user.Validate(); err != nil {
+		return err
+	}
+
 	passwordHash, err := password.HashPassword(user.Password)
 	if err != nil {
 		return err
@@ -118,13 +232,20 @@ func (c *Catalog) RecordUser(ctx context.Context, user *model.User) error {
 	user.PasswordHash = passwordHash
 	user.Token = model.GenerateUserToken()
 
+	if _, err := c.dialect.PrimaryKeyType(user); err != nil {
+		return err
+	}
+
 	return c.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-		_, err := tx.NewInsert().Model(user).Exec(ctx)
-		if err != nil {
+		if err := c.dialect.Create(ctx, tx, user); err != nil {
+			return err
+		}
+
+		if _, _, err := c.issueToken(ctx, tx, user.ID, model.TokenPurposeSession, 0); err != nil {
 			return err
 		}
 
-		return c.enforceTableSizeLimits(ctx, tx, (*model.User)(nil), c.fixedUsers, c.maxUsers)
+		return c.enforceTableSizeLimitsSoft(ctx, tx, (*model.User)(nil), c.maxUsers)
 	})
 }
 
@@ -141,7 +262,132 @@ func (c *Catalog) LoginUser(ctx context.Context, username, passwordText string)
 	return nil, nil
 }
 
+// IssueToken creates and persists a new token for a user, returning the
+// plaintext token. Only its hash is stored, so the plaintext is not
+// recoverable once this call returns; callers must hand it to the user now.
+func (c *Catalog) IssueToken(ctx context.Context, userID int64, purpose string, ttl time.Duration) (string, *model.UserToken, error) {
+	return c.issueToken(ctx, c.db, userID, purpose, ttl)
+}
+
+func (c *Catalog) issueToken(ctx context.Context, db bun.IDB, userID int64, purpose string, ttl time.Duration) (string, *model.UserToken, error) {
+	raw := model.GenerateUserToken()
+	if purpose == model.TokenPurposeReset {
+		raw = model.GenerateResetToken()
+	}
+
+	tok := &model.UserToken{
+		UserID:    userID,
+		TokenHash: model.HashToken(raw),
+		Purpose:   purpose,
+	}
+	if ttl > 0 {
+		tok.ExpiresAt = time.Now().Add(ttl)
+	}
+
+	if _, err := db.NewInsert().Model(tok).Exec(ctx); err != nil {
+		return "", nil, err
+	}
+	return raw, tok, nil
+}
+
+// RevokeToken marks a token as revoked, without deleting it, so ListUserTokens
+// can still show it as part of a user's session history.
+func (c *Catalog) RevokeToken(ctx context.Context, tokenID int64) error {
+	_, err := c.db.NewUpdate().
+		Model((*model.UserToken)(nil)).
+		Set("revoked_at = ?", time.Now()).
+		Where("id = ? AND revoked_at IS NULL", tokenID).
+		Exec(ctx)
+	return err
+}
+
+// ListUserTokens returns every token ever issued to a user, newest first,
+// including expired and revoked ones.
+func (c *Catalog) ListUserTokens(ctx context.Context, userID int64) ([]model.UserToken, error) {
+	var tokens []model.UserToken
+	err := c.db.NewSelect().Model(&tokens).Where("user_id = ?", userID).Order("issued_at DESC").Scan(ctx)
+	return tokens, err
+}
+
+// RotateToken revokes a user's current session tokens and issues a new one
+// in the same transaction, so a token leak can be remediated without
+// locking the user out between the revoke and the reissue.
+func (c *Catalog) RotateToken(ctx context.Context, userID int64) (string, error) {
+	var raw string
+	err := c.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewUpdate().
+			Model((*model.UserToken)(nil)).
+			Set("revoked_at = ?", time.Now()).
+			Where("user_id = ? AND purpose = ? AND revoked_at IS NULL", userID, model.TokenPurposeSession).
+			Exec(ctx); err != nil {
+			return err
+		}
+
+		var err error
+		raw, _, err = c.issueToken(ctx, tx, userID, model.TokenPurposeSession, 0)
+		return err
+	})
+	return raw, err
+}
+
+// RequestPasswordReset issues a single-use, short-TTL reset token for the
+// named user. It returns an empty token without error if the user does not
+// exist, so callers can return a generic response and avoid leaking which
+// usernames are registered.
+func (c *Catalog) RequestPasswordReset(ctx context.Context, username string) (string, error) {
+	var user model.User
+	err := c.db.NewSelect().Model(&user).Where("username = ?", username).Limit(1).Scan(ctx)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	raw, _, err := c.IssueToken(ctx, user.ID, model.TokenPurposeReset, model.ResetTokenTTL)
+	return raw, err
+}
+
+// ConsumeResetToken validates a password-reset token, sets the new password
+// if it is still valid, and revokes the token so it cannot be reused.
+func (c *Catalog) ConsumeResetToken(ctx context.Context, rawToken, newPassword string) error {
+	hash := model.HashToken(rawToken)
+	return c.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var tok model.UserToken
+		err := tx.NewSelect().Model(&tok).Where("token_hash = ? AND purpose = ?", hash, model.TokenPurposeReset).Limit(1).Scan(ctx)
+		if err == sql.ErrNoRows {
+			return errors.New("reset token is invalid")
+		}
+		if err != nil {
+			return err
+		}
+		if !tok.Valid(time.Now()) {
+			return errors.New("reset token has expired or was already used")
+		}
+
+		if err := model.ValidatePassword(newPassword); err != nil {
+			return err
+		}
+
+		passwordHash, err := password.HashPassword(newPassword)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.NewUpdate().Model((*model.User)(nil)).Set("password_hash = ?", passwordHash).Where("id = ?", tok.UserID).Exec(ctx); err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().Model(&tok).Set("revoked_at = ?", time.Now()).Where("id = ?", tok.ID).Exec(ctx)
+		return err
+	})
+}
+
 func (c *Catalog) RecordRecommendation(ctx context.Context, pizza *model.Pizza) error {
+	if err := requireGlobalTable(ctx, "pizzas"); err != nil {
+		return err
+	}
+
 	// Inject an artificial error for testing purposes
 	err := errorinjector.InjectErrors(ctx, "record-recommendation")
 	if err != nil {
@@ -149,9 +395,15 @@ func (c *Catalog) RecordRecommendation(ctx context.Context, pizza *model.Pizza)
 	}
 
 	pizza.DoughID = pizza.Dough.ID
+	if err := pizza.Validate(); err != nil {
+		return err
+	}
+	if _, err := c.dialect.PrimaryKeyType(pizza); err != nil {
+		return err
+	}
+
 	return c.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-		_, err := tx.NewInsert().Model(pizza).Exec(ctx)
-		if err != nil {
+		if err := c.dialect.Create(ctx, tx, pizza); err != nil {
 			return err
 		}
 		for _, i := range pizza.Ingredients {
@@ -161,29 +413,30 @@ func (c *Catalog) RecordRecommendation(ctx context.Context, pizza *model.Pizza)
 			}
 		}
 
-		return c.enforceTableSizeLimits(ctx, tx, (*model.Pizza)(nil), c.fixedPizzas, c.maxPizzas)
+		return c.enforceTableSizeLimitsSoft(ctx, tx, (*model.Pizza)(nil), c.maxPizzas)
 	})
 }
 
-// enforceTableSizeLimits limits the size of a table, which must have an ID row.
-// All rows will be deleted except the N newest ones, where N == maximum.
-// If fixed > 0, then the first K rows (IDs 0, 1, 2...) will never be deleted,
-// where K == fixed (even if this would make the table exceed N rows).
-// If maximum is 0 or negative, then do not enforce any limits.
-// Useful for keeping an in-memory SQLite database size below a certain number.
-func (c *Catalog) enforceTableSizeLimits(ctx context.Context, tx bun.Tx, model any, fixed, maximum int) error {
+// enforceTableSizeLimitsSoft is the request-path fast path for table size
+// enforcement: rather than deleting rows inline (which used to run a
+// DELETE in the same transaction as every RecordUser/RecordRecommendation),
+// it only counts rows and, if the table has grown past maximum*1.1, wakes
+// up RunFlushLoop to do the actual deletion asynchronously. See flushAll
+// for where the DELETE itself happens, and RunFlushLoop for scheduling.
+func (c *Catalog) enforceTableSizeLimitsSoft(ctx context.Context, tx bun.Tx, model any, maximum int) error {
 	if maximum <= 0 {
 		return nil
 	}
-	_, err := tx.NewDelete().
-		Model(model).
-		Where(fmt.Sprintf("id NOT IN (?) AND id > %v", fixed), tx.NewSelect().
-			Model(model).
-			Order("created_at DESC").
-			Column("id").
-			Limit(maximum)).
-		Exec(ctx)
-	return err
+
+	count, err := tx.NewSelect().Model(model).Count(ctx)
+	if err != nil {
+		return err
+	}
+
+	if count > int(float64(maximum)*1.1) {
+		c.signalFlush()
+	}
+	return nil
 }
 
 func envInt(name string, defaultVal int) int {
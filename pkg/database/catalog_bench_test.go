@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+
+	_ "modernc.org/sqlite"
+)
+
+// queryCountHook counts every query bun issues, so the benchmarks below can
+// report query count as a metric instead of just latency.
+type queryCountHook struct{ queries int }
+
+func (h *queryCountHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	h.queries++
+	return ctx
+}
+
+func (h *queryCountHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {}
+
+// newBenchCatalog builds a Catalog against an in-memory SQLite database with
+// just enough schema for GetHistory/GetHistoryBatched, seeded with n pizzas
+// that share a pool of doughs and ingredients.
+func newBenchCatalog(tb testing.TB, n int) (*Catalog, *queryCountHook) {
+	tb.Helper()
+
+	sqldb, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { sqldb.Close() })
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	ctx := context.Background()
+	for _, stmt := range []string{
+		`CREATE TABLE doughs (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)`,
+		`CREATE TABLE ingredients (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, type TEXT)`,
+		`CREATE TABLE pizzas (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, dough_id INTEGER, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE TABLE pizza_to_ingredients (pizza_id INTEGER, ingredient_id INTEGER)`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	const doughCount, ingredientCount = 3, 6
+	for i := 0; i < doughCount; i++ {
+		if _, err := db.ExecContext(ctx, `INSERT INTO doughs (name) VALUES (?)`, "dough"); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	for i := 0; i < ingredientCount; i++ {
+		if _, err := db.ExecContext(ctx, `INSERT INTO ingredients (name, type) VALUES (?, ?)`, "ingredient", "topping"); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	for i := 0; i < n; i++ {
+		doughID := i%doughCount + 1
+		res, err := db.ExecContext(ctx, `INSERT INTO pizzas (name, dough_id) VALUES (?, ?)`, "pizza", doughID)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		pizzaID, err := res.LastInsertId()
+		if err != nil {
+			tb.Fatal(err)
+		}
+		for j := 0; j < 3; j++ {
+			ingredientID := (i+j)%ingredientCount + 1
+			if _, err := db.ExecContext(ctx, `INSERT INTO pizza_to_ingredients (pizza_id, ingredient_id) VALUES (?, ?)`, pizzaID, ingredientID); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	}
+
+	hook := &queryCountHook{}
+	db.AddQueryHook(hook)
+	return &Catalog{db: db, dialect: sqliteDialect{}}, hook
+}
+
+// BenchmarkGetHistory and BenchmarkGetHistoryBatched report queries/op
+// alongside the usual timing, so a reduction (or regression) in query count
+// between the two is visible directly in `go test -bench` output instead of
+// only showing up as a change in latency.
+
+func BenchmarkGetHistory(b *testing.B) {
+	c, hook := newBenchCatalog(b, 50)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.GetHistory(context.Background(), 50); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(hook.queries)/float64(b.N), "queries/op")
+}
+
+func BenchmarkGetHistoryBatched(b *testing.B) {
+	c, hook := newBenchCatalog(b, 50)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.GetHistoryBatched(context.Background(), 50); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(hook.queries)/float64(b.N), "queries/op")
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/mysqldialect"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "modernc.org/sqlite"
+)
+
+// initializeDB opens a connection pool and wraps it in a bun.DB configured
+// for the backend named by connString's scheme (postgres://, cockroach://,
+// mysql://), or SQLite for a scheme-less connString. The Dialect selected
+// here is re-derived by NewCatalog to drive backend-specific behavior
+// elsewhere in this package.
+func initializeDB(connString string) (*bun.DB, error) {
+	dialect, err := dialectForDSN(connString)
+	if err != nil {
+		return nil, err
+	}
+
+	switch dialect.(type) {
+	case sqliteDialect:
+		sqldb, err := sql.Open("sqlite", connString)
+		if err != nil {
+			return nil, fmt.Errorf("opening sqlite connection: %w", err)
+		}
+		return bun.NewDB(sqldb, sqlitedialect.New()), nil
+	case postgresDialect, cockroachDialect:
+		dsn, err := pgConnString(connString)
+		if err != nil {
+			return nil, err
+		}
+		sqldb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(dsn)))
+		return bun.NewDB(sqldb, pgdialect.New()), nil
+	case mysqlDialect:
+		sqldb, err := sql.Open("mysql", mysqlDSN(connString))
+		if err != nil {
+			return nil, fmt.Errorf("opening mysql connection: %w", err)
+		}
+		return bun.NewDB(sqldb, mysqldialect.New()), nil
+	default:
+		return nil, fmt.Errorf("database: no driver wired up for dialect %q", dialect.Name())
+	}
+}
+
+// pgConnString rewrites connString's scheme to "postgres" before it's handed
+// to pgdriver.WithDSN, which only recognizes the "postgres"/"postgresql"
+// schemes and panics on anything else. dialectForDSN also accepts
+// "cockroach"/"cockroachdb" so it can select cockroachDialect, so a
+// CockroachDB connString reaches this function with a scheme pgdriver
+// doesn't understand even though the wire protocol is the same.
+func pgConnString(connString string) (string, error) {
+	u, err := url.Parse(connString)
+	if err != nil {
+		return "", fmt.Errorf("parsing connection string: %w", err)
+	}
+	u.Scheme = "postgres"
+	return u.String(), nil
+}
+
+// mysqlDSN strips the "mysql://" scheme dialectForDSN requires to select
+// mysqlDialect. go-sql-driver/mysql's DSN grammar has no URL scheme, so
+// passing it through unmodified doesn't fail loudly: mysql.ParseDSN instead
+// silently parses "mysql" as the DSN's user and "//" onward as the rest,
+// authenticating with mangled credentials instead of erroring.
+func mysqlDSN(connString string) string {
+	return strings.TrimPrefix(connString, "mysql://")
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/uptrace/bun"
+)
+
+// Dialect captures the handful of behaviors that differ between the
+// database backends QuickPizza can run against. NewCatalog picks an
+// implementation based on the connection string's scheme; the rest of this
+// package talks to Dialect rather than hard-coding per-backend SQL.
+type Dialect interface {
+	// Name identifies the dialect for logging purposes.
+	Name() string
+	// Create inserts model, adapting primary-key handling to whatever the
+	// backend requires.
+	Create(ctx context.Context, tx bun.Tx, model any) error
+	// PrimaryKeyType returns the SQL type used for model's primary key
+	// column, or ErrNoPrimaryKey if model has no ID field.
+	PrimaryKeyType(model any) (string, error)
+	// EnforceTableSizeLimits deletes rows over the table size limits and
+	// reports how many rows were removed; see Catalog.flushAll for
+	// semantics and how it's scheduled.
+	EnforceTableSizeLimits(ctx context.Context, tx bun.Tx, model any, fixed, maximum int) (int64, error)
+	// QuoteIdentifier quotes name the way the backend expects for a raw,
+	// interpolated identifier (a table or column name), so callers that
+	// can't go through bun's query builder can still interpolate safely.
+	QuoteIdentifier(name string) string
+	// CreateTenantTable creates tenantTable, if it doesn't already exist,
+	// by cloning the structure of baseTable. See Catalog.ProvisionTenant.
+	CreateTenantTable(ctx context.Context, tx bun.Tx, tenantTable, baseTable string) error
+}
+
+// ErrNoPrimaryKey is returned by PrimaryKeyType when a model has no ID
+// field, instead of silently guessing a column type.
+var ErrNoPrimaryKey = errors.New("database: model has no ID field")
+
+// dialectForDSN selects a Dialect from a connection string's scheme, e.g.
+// "postgres://...", "cockroach://...", "mysql://...". A connString with no
+// scheme at all (a bare file path, ":memory:", or the empty string) selects
+// sqliteDialect, QuickPizza's zero-config default backend.
+func dialectForDSN(dsn string) (Dialect, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.Scheme == "" {
+		return sqliteDialect{}, nil
+	}
+
+	switch u.Scheme {
+	case "sqlite", "file":
+		return sqliteDialect{}, nil
+	case "postgres", "postgresql":
+		return postgresDialect{}, nil
+	case "cockroach", "cockroachdb":
+		return cockroachDialect{postgresDialect{}}, nil
+	case "mysql":
+		return mysqlDialect{}, nil
+	default:
+		return nil, fmt.Errorf("database: unsupported dialect %q", u.Scheme)
+	}
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+)
+
+// cockroachDialect targets CockroachDB. It speaks the Postgres wire
+// protocol and SQL dialect, so it reuses postgresDialect's Create and
+// PrimaryKeyType; EnforceTableSizeLimits is overridden so the comment
+// explains why instead of leaving the shared behavior to look accidental.
+type cockroachDialect struct {
+	postgresDialect
+}
+
+func (cockroachDialect) Name() string { return "cockroachdb" }
+
+func (d cockroachDialect) EnforceTableSizeLimits(ctx context.Context, tx bun.Tx, model any, fixed, maximum int) (int64, error) {
+	// The query shape is identical to Postgres': CockroachDB understands
+	// the same DELETE ... WHERE id NOT IN (subquery) form, and bounding it
+	// by `maximum` keeps each flush within Cockroach's default statement
+	// size limits without any extra batching logic.
+	return d.commonDialect.enforceTableSizeLimits(ctx, tx, model, fixed, maximum)
+}
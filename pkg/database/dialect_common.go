@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/uptrace/bun"
+)
+
+// commonDialect implements the parts of Dialect that are identical across
+// backends; concrete dialects embed it and override only what differs.
+type commonDialect struct{}
+
+// idFieldKind reflects on model's ID field and returns its Go kind. It
+// returns ErrNoPrimaryKey rather than guessing when model has no such
+// field, mirroring the fix pop made for its issue #565. Concrete dialects
+// use it to pick the SQL type their own PrimaryKeyType returns.
+func idFieldKind(model any) (reflect.Kind, error) {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return 0, fmt.Errorf("%w: %T is not a struct", ErrNoPrimaryKey, model)
+	}
+
+	field, ok := t.FieldByName("ID")
+	if !ok {
+		return 0, fmt.Errorf("%w: %s has no ID field", ErrNoPrimaryKey, t)
+	}
+	return field.Type.Kind(), nil
+}
+
+// isIntegerKind reports whether k is one of Go's integer kinds, the only
+// ones QuickPizza's models use for their ID field.
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// QuoteIdentifier double-quotes name per the SQL standard, escaping any
+// embedded quote. Postgres, CockroachDB and SQLite all accept this form;
+// mysqlDialect overrides it with MySQL's backtick quoting.
+func (commonDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// createTenantTableLike is the Postgres-compatible "clone a table's full
+// structure" DDL shared by postgresDialect and cockroachDialect.
+func (d commonDialect) createTenantTableLike(ctx context.Context, tx bun.Tx, tenantTable, baseTable string) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (LIKE %s INCLUDING ALL)",
+		d.QuoteIdentifier(tenantTable), d.QuoteIdentifier(baseTable),
+	))
+	return err
+}
+
+// enforceTableSizeLimits is the Postgres-compatible implementation shared by
+// postgresDialect and cockroachDialect; see Catalog.flushAll for the
+// semantics it implements.
+func (commonDialect) enforceTableSizeLimits(ctx context.Context, tx bun.Tx, model any, fixed, maximum int) (int64, error) {
+	if maximum <= 0 {
+		return 0, nil
+	}
+	res, err := tx.NewDelete().
+		Model(model).
+		Where(fmt.Sprintf("id NOT IN (?) AND id > %v", fixed), tx.NewSelect().
+			Model(model).
+			Order("created_at DESC").
+			Column("id").
+			Limit(maximum)).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
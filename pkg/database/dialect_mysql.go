@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/uptrace/bun"
+)
+
+// mysqlDialect targets MySQL/MariaDB. MySQL rejects a DELETE whose subquery
+// reads from the table being deleted from, so EnforceTableSizeLimits wraps
+// the "rows to keep" subquery in a derived table to work around it.
+type mysqlDialect struct {
+	commonDialect
+}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+// QuoteIdentifier overrides commonDialect's double-quote form with MySQL's
+// backtick quoting, escaping any embedded backtick.
+func (mysqlDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// CreateTenantTable uses MySQL's "CREATE TABLE ... LIKE" form, which clones
+// column definitions, indexes and keys but, unlike Postgres' INCLUDING ALL,
+// has no equivalent single keyword; MySQL doesn't need one since LIKE
+// already copies everything CREATE TABLE ... LIKE supports.
+func (d mysqlDialect) CreateTenantTable(ctx context.Context, tx bun.Tx, tenantTable, baseTable string) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s LIKE %s",
+		d.QuoteIdentifier(tenantTable), d.QuoteIdentifier(baseTable),
+	))
+	return err
+}
+
+func (mysqlDialect) Create(ctx context.Context, tx bun.Tx, model any) error {
+	_, err := tx.NewInsert().Model(model).Exec(ctx)
+	return err
+}
+
+func (mysqlDialect) PrimaryKeyType(model any) (string, error) {
+	kind, err := idFieldKind(model)
+	if err != nil {
+		return "", err
+	}
+	if !isIntegerKind(kind) {
+		return "", fmt.Errorf("database: unsupported primary key kind %s", kind)
+	}
+	return "BIGINT AUTO_INCREMENT", nil
+}
+
+func (mysqlDialect) EnforceTableSizeLimits(ctx context.Context, tx bun.Tx, model any, fixed, maximum int) (int64, error) {
+	if maximum <= 0 {
+		return 0, nil
+	}
+	res, err := tx.NewDelete().
+		Model(model).
+		Where(fmt.Sprintf("id NOT IN (SELECT id FROM (?) AS keep) AND id > %v", fixed), tx.NewSelect().
+			Model(model).
+			Order("created_at DESC").
+			Column("id").
+			Limit(maximum)).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+// postgresDialect targets stock PostgreSQL. Standard SQL is enough here;
+// everything comes from commonDialect.
+type postgresDialect struct {
+	commonDialect
+}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) Create(ctx context.Context, tx bun.Tx, model any) error {
+	_, err := tx.NewInsert().Model(model).Exec(ctx)
+	return err
+}
+
+func (postgresDialect) PrimaryKeyType(model any) (string, error) {
+	kind, err := idFieldKind(model)
+	if err != nil {
+		return "", err
+	}
+	if !isIntegerKind(kind) {
+		return "", fmt.Errorf("database: unsupported primary key kind %s", kind)
+	}
+	return "BIGSERIAL", nil
+}
+
+func (d postgresDialect) EnforceTableSizeLimits(ctx context.Context, tx bun.Tx, model any, fixed, maximum int) (int64, error) {
+	return d.commonDialect.enforceTableSizeLimits(ctx, tx, model, fixed, maximum)
+}
+
+func (d postgresDialect) CreateTenantTable(ctx context.Context, tx bun.Tx, tenantTable, baseTable string) error {
+	return d.commonDialect.createTenantTableLike(ctx, tx, tenantTable, baseTable)
+}
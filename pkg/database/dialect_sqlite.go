@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+)
+
+// sqliteDialect targets the bundled, zero-config SQLite database QuickPizza
+// defaults to when connString carries no recognized scheme (e.g. a bare
+// file path or ":memory:"). It shares commonDialect's identifier quoting
+// and the Postgres-style DELETE ... WHERE id NOT IN (subquery) row-limit
+// enforcement, which SQLite also understands natively.
+type sqliteDialect struct {
+	commonDialect
+}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) Create(ctx context.Context, tx bun.Tx, model any) error {
+	_, err := tx.NewInsert().Model(model).Exec(ctx)
+	return err
+}
+
+func (sqliteDialect) PrimaryKeyType(model any) (string, error) {
+	kind, err := idFieldKind(model)
+	if err != nil {
+		return "", err
+	}
+	if !isIntegerKind(kind) {
+		return "", fmt.Errorf("database: unsupported primary key kind %s", kind)
+	}
+	// SQLite aliases any INTEGER PRIMARY KEY column to its implicit rowid,
+	// which is all the autoincrement behavior QuickPizza's models need.
+	return "INTEGER", nil
+}
+
+func (d sqliteDialect) EnforceTableSizeLimits(ctx context.Context, tx bun.Tx, model any, fixed, maximum int) (int64, error) {
+	return d.commonDialect.enforceTableSizeLimits(ctx, tx, model, fixed, maximum)
+}
+
+// CreateTenantTable falls back to "CREATE TABLE ... AS SELECT ... WHERE 0":
+// SQLite has no "LIKE"/"INCLUDING ALL" equivalent, and this is enough to
+// clone column names and types for the one table (users) this repo
+// currently provisions per tenant.
+func (d sqliteDialect) CreateTenantTable(ctx context.Context, tx bun.Tx, tenantTable, baseTable string) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s AS SELECT * FROM %s WHERE 0",
+		d.QuoteIdentifier(tenantTable), d.QuoteIdentifier(baseTable),
+	))
+	return err
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDialectForDSN(t *testing.T) {
+	cases := []struct {
+		dsn  string
+		want string
+	}{
+		{"", "sqlite"},
+		{":memory:", "sqlite"},
+		{"file:test.db", "sqlite"},
+		{"sqlite://test.db", "sqlite"},
+		{"postgres://localhost/quickpizza", "postgres"},
+		{"postgresql://localhost/quickpizza", "postgres"},
+		{"cockroach://localhost/quickpizza", "cockroachdb"},
+		{"cockroachdb://localhost/quickpizza", "cockroachdb"},
+		{"mysql://localhost/quickpizza", "mysql"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.dsn, func(t *testing.T) {
+			d, err := dialectForDSN(tc.dsn)
+			if err != nil {
+				t.Fatalf("dialectForDSN(%q): %v", tc.dsn, err)
+			}
+			if d.Name() != tc.want {
+				t.Errorf("dialectForDSN(%q).Name() = %q, want %q", tc.dsn, d.Name(), tc.want)
+			}
+		})
+	}
+}
+
+func TestDialectForDSNUnsupportedScheme(t *testing.T) {
+	if _, err := dialectForDSN("mongodb://localhost/quickpizza"); err == nil {
+		t.Error("dialectForDSN should reject an unrecognized scheme")
+	}
+}
+
+type noIDModel struct{}
+
+type stringIDModel struct {
+	ID string
+}
+
+type intIDModel struct {
+	ID int64
+}
+
+func TestPrimaryKeyTypeNoIDField(t *testing.T) {
+	for _, d := range []Dialect{postgresDialect{}, mysqlDialect{}, sqliteDialect{}} {
+		if _, err := d.PrimaryKeyType(&noIDModel{}); !errors.Is(err, ErrNoPrimaryKey) {
+			t.Errorf("%s: PrimaryKeyType(&noIDModel{}) error = %v, want ErrNoPrimaryKey", d.Name(), err)
+		}
+	}
+}
+
+func TestPrimaryKeyTypeUnsupportedKind(t *testing.T) {
+	for _, d := range []Dialect{postgresDialect{}, mysqlDialect{}, sqliteDialect{}} {
+		if _, err := d.PrimaryKeyType(&stringIDModel{}); err == nil {
+			t.Errorf("%s: PrimaryKeyType(&stringIDModel{}) should reject a non-integer ID field", d.Name())
+		}
+	}
+}
+
+func TestPrimaryKeyTypeReturnsSQLType(t *testing.T) {
+	cases := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{postgresDialect{}, "BIGSERIAL"},
+		{cockroachDialect{postgresDialect{}}, "BIGSERIAL"},
+		{mysqlDialect{}, "BIGINT AUTO_INCREMENT"},
+		{sqliteDialect{}, "INTEGER"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.dialect.Name(), func(t *testing.T) {
+			got, err := tc.dialect.PrimaryKeyType(&intIDModel{})
+			if err != nil {
+				t.Fatalf("PrimaryKeyType: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("PrimaryKeyType() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	if got, want := (postgresDialect{}).QuoteIdentifier(`t_acme_users`), `"t_acme_users"`; got != want {
+		t.Errorf("postgresDialect.QuoteIdentifier() = %s, want %s", got, want)
+	}
+	if got, want := (mysqlDialect{}).QuoteIdentifier(`t_acme_users`), "`t_acme_users`"; got != want {
+		t.Errorf("mysqlDialect.QuoteIdentifier() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEnvDurationDefault(t *testing.T) {
+	const name = "QUICKPIZZA_TEST_FLUSH_INTERVAL_UNSET"
+	os.Unsetenv(name)
+	if got, want := envDuration(name, 30*time.Second), 30*time.Second; got != want {
+		t.Errorf("envDuration() = %v, want %v", got, want)
+	}
+}
+
+func TestEnvDurationParsed(t *testing.T) {
+	const name = "QUICKPIZZA_TEST_FLUSH_INTERVAL_SET"
+	t.Setenv(name, "5m")
+	if got, want := envDuration(name, 30*time.Second), 5*time.Minute; got != want {
+		t.Errorf("envDuration() = %v, want %v", got, want)
+	}
+}
+
+func TestEnvDurationInvalidFallsBackToDefault(t *testing.T) {
+	const name = "QUICKPIZZA_TEST_FLUSH_INTERVAL_INVALID"
+	t.Setenv(name, "not-a-duration")
+	if got, want := envDuration(name, 30*time.Second), 30*time.Second; got != want {
+		t.Errorf("envDuration() = %v, want %v", got, want)
+	}
+}
+
+func TestSignalFlushDoesNotBlockOrQueue(t *testing.T) {
+	c := &Catalog{flushSignal: make(chan struct{}, 1)}
+
+	// Two signals in a row should not block, and only one pending signal
+	// should be observable: there's nothing to gain from queuing more.
+	c.signalFlush()
+	c.signalFlush()
+
+	select {
+	case <-c.flushSignal:
+	default:
+		t.Fatal("expected a pending flush signal")
+	}
+
+	select {
+	case <-c.flushSignal:
+		t.Fatal("expected at most one pending flush signal")
+	default:
+	}
+}
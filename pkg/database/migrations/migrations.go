@@ -0,0 +1,145 @@
+// Package migrations holds the SQL migrations applied by database.Catalog.
+//
+// A dialect-neutral migration ships as a single pair of files, e.g.
+// "20240301000000_create_whatever.up.sql" / "....down.sql". A migration
+// that needs backend-specific SQL instead ships one pair per dialect, e.g.
+// "..._create_user_tokens.postgres.up.sql" and
+// "..._create_user_tokens.mysql.up.sql"; For picks the right one at
+// runtime and falls back to the dialect-neutral file when no
+// dialect-specific version exists for a given file (up or down
+// independently, so a migration can have a dialect-specific .up.sql and a
+// shared, dialect-neutral .down.sql).
+package migrations
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun/migrate"
+)
+
+//go:embed *.sql
+var sqlMigrations embed.FS
+
+// For returns the migration set to apply for the named dialect ("postgres",
+// "cockroachdb", "mysql", "sqlite").
+func For(dialectName string) (*migrate.Migrations, error) {
+	filtered, err := filterDialect(sqlMigrations, dialectName)
+	if err != nil {
+		return nil, fmt.Errorf("selecting %s migrations: %w", dialectName, err)
+	}
+
+	m := migrate.NewMigrations()
+	if err := m.Discover(filtered); err != nil {
+		return nil, fmt.Errorf("discovering %s migrations: %w", dialectName, err)
+	}
+	return m, nil
+}
+
+// filterDialect returns a view of fsys where, for any file that ships a
+// dialect-specific variant ("name.<dialect>.up.sql"), only that variant is
+// kept (renamed to the dialect-neutral "name.up.sql" bun's Discover
+// expects) and every other dialect's version of that same file is dropped.
+// A file with no dialect-specific variant passes through unchanged. Up and
+// down files are tracked independently, so a migration can mix a
+// dialect-specific .up.sql with a shared, dialect-neutral .down.sql.
+func filterDialect(fsys embed.FS, dialectName string) (fs.FS, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string][]byte{}
+	hasDialectVariant := map[string]bool{}
+
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.Contains(name, "."+dialectName+".") {
+			continue
+		}
+		data, err := fsys.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		plain := strings.Replace(name, "."+dialectName+".", ".", 1)
+		out[plain] = data
+		hasDialectVariant[plain] = true
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if isDialectSpecific(name) {
+			continue
+		}
+		if hasDialectVariant[name] {
+			continue
+		}
+		data, err := fsys.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = data
+	}
+
+	return mapFS(out), nil
+}
+
+func isDialectSpecific(name string) bool {
+	parts := strings.Split(name, ".")
+	// name.up.sql / name.down.sql -> 3 parts; anything longer carries a
+	// dialect infix, e.g. name.postgres.up.sql.
+	return len(parts) > 3
+}
+
+// mapFS is a minimal in-memory fs.FS/fs.ReadDirFS over a flat set of files,
+// used to hand migrate.Migrations.Discover a dialect-filtered view of
+// sqlMigrations without writing anything to disk.
+type mapFS map[string][]byte
+
+func (m mapFS) Open(name string) (fs.File, error) {
+	data, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{name: name, Reader: bytes.NewReader(data), size: int64(len(data))}, nil
+}
+
+func (m mapFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	entries := make([]fs.DirEntry, 0, len(m))
+	for name, data := range m {
+		entries = append(entries, memDirEntry{name: name, size: int64(len(data))})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+type memFile struct {
+	*bytes.Reader
+	name string
+	size int64
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return memDirEntry{name: f.name, size: f.size}, nil }
+func (f *memFile) Close() error               { return nil }
+
+type memDirEntry struct {
+	name string
+	size int64
+}
+
+func (e memDirEntry) Name() string              { return e.name }
+func (e memDirEntry) IsDir() bool                { return false }
+func (e memDirEntry) Type() fs.FileMode          { return 0 }
+func (e memDirEntry) Info() (fs.FileInfo, error) { return e, nil }
+func (e memDirEntry) Size() int64                { return e.size }
+func (e memDirEntry) Mode() fs.FileMode          { return 0 }
+func (e memDirEntry) ModTime() time.Time         { return time.Time{} }
+func (e memDirEntry) Sys() any                   { return nil }
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTenantTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+
+	sqldb, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqldb.Close() })
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	if _, err := db.ExecContext(context.Background(), `CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, username TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+
+	return &Catalog{db: db, dialect: sqliteDialect{}}
+}
+
+func TestProvisionTenantRejectsInvalidID(t *testing.T) {
+	c := newTenantTestCatalog(t)
+	if err := c.ProvisionTenant(context.Background(), "acme; DROP TABLE users; --"); err == nil {
+		t.Error("ProvisionTenant should reject an invalid tenant id")
+	}
+}
+
+func TestGlobalTableMethodsRejectTenantContext(t *testing.T) {
+	c := newTenantTestCatalog(t)
+	ctx, err := c.WithTenant(context.Background(), "acme")
+	if err != nil {
+		t.Fatalf("WithTenant: %v", err)
+	}
+
+	if _, err := c.GetDoughs(ctx); err == nil {
+		t.Error("GetDoughs should refuse to run under a tenant, since doughs aren't tenant-scoped yet")
+	}
+	if _, err := c.GetIngredients(ctx, "veg"); err == nil {
+		t.Error("GetIngredients should refuse to run under a tenant, since ingredients aren't tenant-scoped yet")
+	}
+	if _, err := c.GetHistory(ctx, 10); err == nil {
+		t.Error("GetHistory should refuse to run under a tenant, since pizzas aren't tenant-scoped yet")
+	}
+}
+
+func TestProvisionTenantCreatesTable(t *testing.T) {
+	c := newTenantTestCatalog(t)
+	ctx := context.Background()
+
+	if err := c.ProvisionTenant(ctx, "acme"); err != nil {
+		t.Fatalf("ProvisionTenant: %v", err)
+	}
+
+	var name string
+	err := c.db.NewSelect().
+		ColumnExpr("name").
+		TableExpr("sqlite_master").
+		Where("type = 'table' AND name = ?", "t_acme_users").
+		Scan(ctx, &name)
+	if err != nil {
+		t.Fatalf("looking up provisioned table: %v", err)
+	}
+	if name != "t_acme_users" {
+		t.Errorf("provisioned table name = %q, want %q", name, "t_acme_users")
+	}
+}
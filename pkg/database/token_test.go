@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+
+	"github.com/grafana/quickpizza/pkg/model"
+
+	_ "modernc.org/sqlite"
+)
+
+// newTokenTestCatalog builds a Catalog against an in-memory SQLite database
+// with just enough schema (users, user_tokens) to exercise the token flows
+// below, seeded with a single user with ID 1.
+func newTokenTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+
+	sqldb, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqldb.Close() })
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	ctx := context.Background()
+	for _, stmt := range []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, username TEXT UNIQUE, token TEXT UNIQUE, password_hash TEXT)`,
+		`CREATE TABLE user_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			purpose TEXT NOT NULL,
+			issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP,
+			revoked_at TIMESTAMP
+		)`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.RegisterModel((*model.UserToken)(nil))
+
+	if _, err := db.ExecContext(ctx, `INSERT INTO users (username, token, password_hash) VALUES (?, ?, ?)`, "alice", "tok", "hash"); err != nil {
+		t.Fatal(err)
+	}
+
+	return &Catalog{db: db, dialect: sqliteDialect{}, flushSignal: make(chan struct{}, 1)}
+}
+
+func TestIssueAndRevokeToken(t *testing.T) {
+	c := newTokenTestCatalog(t)
+	ctx := context.Background()
+
+	raw, tok, err := c.IssueToken(ctx, 1, model.TokenPurposeSession, 0)
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+	if raw == "" || tok.ID == 0 {
+		t.Fatalf("IssueToken returned a zero-value token: %+v", tok)
+	}
+	if !tok.Valid(time.Now()) {
+		t.Error("a freshly issued token should be valid")
+	}
+
+	if err := c.RevokeToken(ctx, tok.ID); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+
+	tokens, err := c.ListUserTokens(ctx, 1)
+	if err != nil {
+		t.Fatalf("ListUserTokens: %v", err)
+	}
+	if len(tokens) != 1 || !tokens[0].Revoked() {
+		t.Fatalf("ListUserTokens = %+v, want exactly one revoked token", tokens)
+	}
+}
+
+func TestRotateToken(t *testing.T) {
+	c := newTokenTestCatalog(t)
+	ctx := context.Background()
+
+	first, _, err := c.IssueToken(ctx, 1, model.TokenPurposeSession, 0)
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+
+	second, err := c.RotateToken(ctx, 1)
+	if err != nil {
+		t.Fatalf("RotateToken: %v", err)
+	}
+	if second == "" || second == first {
+		t.Error("RotateToken should issue a new, distinct token")
+	}
+
+	tokens, err := c.ListUserTokens(ctx, 1)
+	if err != nil {
+		t.Fatalf("ListUserTokens: %v", err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("ListUserTokens returned %d tokens, want 2", len(tokens))
+	}
+
+	var revoked int
+	for _, tk := range tokens {
+		if tk.Revoked() {
+			revoked++
+		}
+	}
+	if revoked != 1 {
+		t.Errorf("RotateToken should revoke exactly the prior session token, got %d revoked", revoked)
+	}
+}
+
+func TestConsumeResetTokenRejectsEmptyPassword(t *testing.T) {
+	c := newTokenTestCatalog(t)
+	ctx := context.Background()
+
+	raw, _, err := c.IssueToken(ctx, 1, model.TokenPurposeReset, model.ResetTokenTTL)
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+
+	if err := c.ConsumeResetToken(ctx, raw, ""); err == nil {
+		t.Error("ConsumeResetToken should reject an empty new password")
+	}
+}
+
+func TestConsumeResetTokenIsSingleUse(t *testing.T) {
+	c := newTokenTestCatalog(t)
+	ctx := context.Background()
+
+	raw, _, err := c.IssueToken(ctx, 1, model.TokenPurposeReset, model.ResetTokenTTL)
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+
+	if err := c.ConsumeResetToken(ctx, raw, "new-password"); err != nil {
+		t.Fatalf("ConsumeResetToken: %v", err)
+	}
+
+	if err := c.ConsumeResetToken(ctx, raw, "another-password"); err == nil {
+		t.Error("ConsumeResetToken should reject a token that was already consumed")
+	}
+}
@@ -0,0 +1,24 @@
+package model
+
+import "fmt"
+
+// Validate reports whether i.Name is set and no longer than MaxNameLength.
+func (i *Ingredient) Validate() error {
+	var errs ValidationErrors
+
+	switch {
+	case i.Name == "":
+		errs = append(errs, ValidationError{Field: "name", Code: ErrIngredientNameEmpty, Message: "ingredient name is empty"})
+	case len(i.Name) > MaxNameLength:
+		errs = append(errs, ValidationError{
+			Field: "name", Code: ErrIngredientNameTooLong,
+			Message: fmt.Sprintf("ingredient name must be %d characters or fewer", MaxNameLength),
+			Limit:   MaxNameLength,
+		})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,30 @@
+package model
+
+import "fmt"
+
+// Validate reports whether p has between one and MaxIngredientsPerPizza
+// ingredients and a dough, accumulating every violation found rather than
+// stopping at the first.
+func (p *Pizza) Validate() error {
+	var errs ValidationErrors
+
+	switch {
+	case len(p.Ingredients) == 0:
+		errs = append(errs, ValidationError{Field: "ingredients", Code: ErrTooFewIngredients, Message: "pizza must have at least one ingredient"})
+	case len(p.Ingredients) > MaxIngredientsPerPizza:
+		errs = append(errs, ValidationError{
+			Field: "ingredients", Code: ErrTooManyIngredients,
+			Message: fmt.Sprintf("pizza may not have more than %d ingredients", MaxIngredientsPerPizza),
+			Limit:   MaxIngredientsPerPizza,
+		})
+	}
+
+	if p.DoughID == 0 && p.Dough.ID == 0 {
+		errs = append(errs, ValidationError{Field: "dough", Code: ErrDoughRequired, Message: "pizza must have a dough"})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
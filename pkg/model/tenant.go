@@ -0,0 +1,100 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/uptrace/bun"
+)
+
+// tenantIDPattern restricts tenant IDs to characters that are always safe to
+// interpolate into a table name, so TenantTableName's output never needs
+// escaping wherever it ends up in SQL (including the raw DDL
+// Catalog.ProvisionTenant issues, which bun's query builder doesn't cover).
+var tenantIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// ValidTenantID reports whether id is safe to use as a tenant identifier.
+func ValidTenantID(id string) bool {
+	return tenantIDPattern.MatchString(id)
+}
+
+type tenantCtxKey struct{}
+
+// TenantAware is implemented by models that are partitioned per tenant.
+// BaseTableName returns the table name as it would be without a tenant
+// prefix (e.g. "users"); TenantedModel uses it to compute the table a given
+// query should actually run against.
+//
+// User is the only model that implements it today. Pizza, Ingredient and
+// Dough are not tenant-scoped yet: their catalog/history/recommendation
+// tables stay shared across tenants until those models also embed
+// TenantedModel and declare a BaseTableName.
+type TenantAware interface {
+	BaseTableName() string
+}
+
+// TenantedModel is embedded by every tenant-scoped model (currently just
+// User) alongside bun.BaseModel. It implements bun's BeforeAppendModelHook,
+// which bun calls while building a query, so the table name can be
+// rewritten per-call based on the tenant carried on ctx rather than being
+// fixed at struct-definition time.
+type TenantedModel struct{}
+
+var _ bun.BeforeAppendModelHook = (*TenantedModel)(nil)
+
+func (TenantedModel) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	tenantID, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	m := query.GetModel()
+	if m == nil {
+		return nil
+	}
+
+	aware, ok := m.Value().(TenantAware)
+	if !ok {
+		return nil
+	}
+
+	table := TenantTableName(tenantID, aware.BaseTableName())
+	switch q := query.(type) {
+	case *bun.SelectQuery:
+		q.ModelTableExpr("?", bun.Ident(table))
+	case *bun.InsertQuery:
+		q.ModelTableExpr("?", bun.Ident(table))
+	case *bun.UpdateQuery:
+		q.ModelTableExpr("?", bun.Ident(table))
+	case *bun.DeleteQuery:
+		q.ModelTableExpr("?", bun.Ident(table))
+	}
+	return nil
+}
+
+// WithTenantContext returns a copy of ctx that scopes queries made with it
+// to tenantID's tables. An empty tenantID is treated as "no tenant". It
+// rejects any tenantID ValidTenantID doesn't accept, since this is the
+// choke point every tenant-scoped table name is derived from.
+func WithTenantContext(ctx context.Context, tenantID string) (context.Context, error) {
+	if tenantID == "" {
+		return ctx, nil
+	}
+	if !ValidTenantID(tenantID) {
+		return ctx, fmt.Errorf("model: invalid tenant id %q", tenantID)
+	}
+	return context.WithValue(ctx, tenantCtxKey{}, tenantID), nil
+}
+
+// TenantFromContext returns the tenant ID carried on ctx, if any.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantCtxKey{}).(string)
+	return id, ok && id != ""
+}
+
+// TenantTableName returns the per-tenant table name for a base table, e.g.
+// "pizzas" becomes "t_acme_pizzas" for tenant "acme".
+func TenantTableName(tenantID, base string) string {
+	return fmt.Sprintf("t_%s_%s", tenantID, base)
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"context"
+	"testing"
+)
+
+func TestValidTenantID(t *testing.T) {
+	valid := []string{"acme", "acme_corp", "Acme123", "a"}
+	for _, id := range valid {
+		if !ValidTenantID(id) {
+			t.Errorf("ValidTenantID(%q) = false, want true", id)
+		}
+	}
+
+	invalid := []string{"", "acme corp", "acme;drop table users;--", "acme-corp", "acme'"}
+	for _, id := range invalid {
+		if ValidTenantID(id) {
+			t.Errorf("ValidTenantID(%q) = true, want false", id)
+		}
+	}
+}
+
+func TestTenantTableName(t *testing.T) {
+	if got, want := TenantTableName("acme", "pizzas"), "t_acme_pizzas"; got != want {
+		t.Errorf("TenantTableName() = %q, want %q", got, want)
+	}
+}
+
+func TestWithTenantContextRoundTrip(t *testing.T) {
+	ctx, err := WithTenantContext(context.Background(), "acme")
+	if err != nil {
+		t.Fatalf("WithTenantContext: %v", err)
+	}
+	id, ok := TenantFromContext(ctx)
+	if !ok || id != "acme" {
+		t.Errorf("TenantFromContext() = (%q, %v), want (%q, true)", id, ok, "acme")
+	}
+}
+
+func TestWithTenantContextRejectsInvalidID(t *testing.T) {
+	_, err := WithTenantContext(context.Background(), "acme; DROP TABLE users; --")
+	if err == nil {
+		t.Error("WithTenantContext should reject a tenant id with invalid characters")
+	}
+}
+
+func TestWithTenantContextEmptyIsNoTenant(t *testing.T) {
+	ctx, err := WithTenantContext(context.Background(), "")
+	if err != nil {
+		t.Fatalf("WithTenantContext: %v", err)
+	}
+	if _, ok := TenantFromContext(ctx); ok {
+		t.Error("an empty tenant id should not be carried on the context")
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHashTokenDeterministic(t *testing.T) {
+	if HashToken("abc123") != HashToken("abc123") {
+		t.Error("HashToken should be deterministic for the same input")
+	}
+	if HashToken("abc123") == HashToken("xyz789") {
+		t.Error("HashToken should differ for different inputs")
+	}
+}
+
+func TestGenerateResetTokenLength(t *testing.T) {
+	raw := GenerateResetToken()
+	if len(raw) != ResetTokenLength {
+		t.Errorf("GenerateResetToken length = %d, want %d", len(raw), ResetTokenLength)
+	}
+}
+
+func TestUserTokenValid(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		tok  UserToken
+		want bool
+	}{
+		{"no expiry, not revoked", UserToken{}, true},
+		{"expired", UserToken{ExpiresAt: now.Add(-time.Minute)}, false},
+		{"not yet expired", UserToken{ExpiresAt: now.Add(time.Minute)}, true},
+		{"revoked", UserToken{RevokedAt: now.Add(-time.Minute)}, false},
+		{"expired and revoked", UserToken{ExpiresAt: now.Add(-time.Minute), RevokedAt: now.Add(-time.Minute)}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.tok.Valid(now); got != tc.want {
+				t.Errorf("Valid() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
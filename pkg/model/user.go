@@ -1,8 +1,9 @@
 package model
 
 import (
-	"errors"
-	"math/rand"
+	"crypto/rand"
+	"fmt"
+	"math/big"
 
 	"github.com/uptrace/bun"
 )
@@ -14,36 +15,81 @@ const (
 
 var characters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
 
+// GenerateUserToken returns a cryptographically random token suitable for use
+// as a session identifier. Callers that need a revocable, expiring token
+// backed by storage should use Catalog.IssueToken instead.
 func GenerateUserToken() string {
-	data := make([]rune, UserTokenLength)
+	return mustRandomString(UserTokenLength)
+}
+
+func mustRandomString(length int) string {
+	data := make([]rune, length)
+	max := big.NewInt(int64(len(characters)))
 	for i := range data {
-		// NOTE: This should use a cryptographically-safe random
-		// number generator instead.
-		data[i] = characters[rand.Intn(len(characters))]
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		data[i] = characters[n.Int64()]
 	}
 	return string(data)
 }
 
+// Validate checks u against all of the username/password rules and
+// accumulates every violation found, rather than stopping at the first, so
+// an HTTP handler can return them all as field-level errors in one response.
 func (u *User) Validate() error {
+	var errs ValidationErrors
+
 	switch {
 	case u.Username == "":
-		return errors.New("username field is empty")
+		errs = append(errs, ValidationError{Field: "username", Code: ErrUsernameEmpty, Message: "username field is empty"})
 	case len(u.Username) > MaxNameLength:
-		return errors.New("username field is too long")
+		errs = append(errs, ValidationError{
+			Field: "username", Code: ErrUsernameTooLong,
+			Message: fmt.Sprintf("username must be %d characters or fewer", MaxNameLength),
+			Limit:   MaxNameLength,
+		})
 	case u.Username == "default":
-		return errors.New("username field is invalid")
-	case u.Password == "":
-		return errors.New("password is empty")
-	default:
+		errs = append(errs, ValidationError{Field: "username", Code: ErrUsernameReserved, Message: "username field is invalid"})
+	}
+
+	if err := ValidatePassword(u.Password); err != nil {
+		errs = append(errs, err.(ValidationErrors)...)
+	}
+
+	if len(errs) == 0 {
 		return nil
 	}
+	return errs
+}
+
+// ValidatePassword checks password against the same rule RecordUser enforces
+// on User.Password, so a password set outside of User.Validate (e.g. by a
+// password reset) can't bypass it. It returns ValidationErrors, or nil if
+// password is acceptable.
+func ValidatePassword(password string) error {
+	if password == "" {
+		return ValidationErrors{{Field: "password", Code: ErrPasswordEmpty, Message: "password is empty"}}
+	}
+	return nil
 }
 
 type User struct {
 	bun.BaseModel
+	TenantedModel
+
 	ID           int64  `bun:",pk,autoincrement"`
 	Username     string `json:"username" bun:",unique"`
 	Token        string `json:"-" bun:",unique"`
 	Password     string `json:"password,omitempty" bun:"-"`
 	PasswordHash string `json:"-"`
 }
+
+var _ TenantAware = (*User)(nil)
+
+// BaseTableName returns the table users are stored in absent a tenant on
+// the context; see TenantedModel.
+func (*User) BaseTableName() string {
+	return "users"
+}
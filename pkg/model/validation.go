@@ -0,0 +1,72 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError carries enough detail about a single failed validation
+// rule for an HTTP handler to return a field-level message, instead of the
+// opaque strings errors.New produced previously.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Limit is the violated numeric limit, if any (e.g. a max length);
+	// zero when the rule isn't limit-based.
+	Limit int `json:"limit,omitempty"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors accumulates every ValidationError found while validating
+// a single value, so callers can report all of them at once rather than
+// stopping at the first.
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Problem renders errs as an RFC 7807 application/problem+json payload.
+type Problem struct {
+	Type   string            `json:"type"`
+	Title  string            `json:"title"`
+	Status int               `json:"status"`
+	Errors []ValidationError `json:"errors"`
+}
+
+// Problem returns the RFC 7807 representation of errs, ready to be
+// marshaled as the body of a 400 response.
+func (errs ValidationErrors) Problem() Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  "validation failed",
+		Status: 400,
+		Errors: errs,
+	}
+}
+
+const (
+	ErrUsernameEmpty    = "username_empty"
+	ErrUsernameTooLong  = "username_too_long"
+	ErrUsernameReserved = "username_reserved"
+	ErrPasswordEmpty    = "password_empty"
+
+	ErrTooFewIngredients  = "too_few_ingredients"
+	ErrTooManyIngredients = "too_many_ingredients"
+	ErrDoughRequired      = "dough_required"
+
+	ErrIngredientNameEmpty   = "ingredient_name_empty"
+	ErrIngredientNameTooLong = "ingredient_name_too_long"
+)
+
+// MaxIngredientsPerPizza caps how many ingredients a single pizza may carry,
+// the same way MaxNameLength caps username/ingredient-name length.
+const MaxIngredientsPerPizza = 10
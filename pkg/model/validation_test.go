@@ -0,0 +1,90 @@
+package model
+
+import "testing"
+
+func hasCode(errs ValidationErrors, code string) bool {
+	for _, e := range errs {
+		if e.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUserValidateAccumulates(t *testing.T) {
+	u := &User{Username: "", Password: ""}
+	err := u.Validate()
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("Validate() error type = %T, want ValidationErrors", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Validate() returned %d errors, want 2 (username + password): %v", len(errs), errs)
+	}
+	if !hasCode(errs, ErrUsernameEmpty) || !hasCode(errs, ErrPasswordEmpty) {
+		t.Errorf("Validate() = %v, want both %s and %s", errs, ErrUsernameEmpty, ErrPasswordEmpty)
+	}
+}
+
+func TestUserValidateOK(t *testing.T) {
+	u := &User{Username: "alice", Password: "hunter2"}
+	if err := u.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	if err := ValidatePassword(""); err == nil {
+		t.Error("ValidatePassword(\"\") should reject an empty password")
+	}
+	if err := ValidatePassword("hunter2"); err != nil {
+		t.Errorf("ValidatePassword(\"hunter2\") = %v, want nil", err)
+	}
+}
+
+func TestPizzaValidateTooFewIngredients(t *testing.T) {
+	p := &Pizza{DoughID: 1}
+	err := p.Validate()
+	errs, ok := err.(ValidationErrors)
+	if !ok || !hasCode(errs, ErrTooFewIngredients) {
+		t.Errorf("Validate() = %v, want %s", err, ErrTooFewIngredients)
+	}
+}
+
+func TestPizzaValidateTooManyIngredients(t *testing.T) {
+	p := &Pizza{DoughID: 1, Ingredients: make([]Ingredient, MaxIngredientsPerPizza+1)}
+	err := p.Validate()
+	errs, ok := err.(ValidationErrors)
+	if !ok || !hasCode(errs, ErrTooManyIngredients) {
+		t.Errorf("Validate() = %v, want %s", err, ErrTooManyIngredients)
+	}
+}
+
+func TestPizzaValidateRequiresDough(t *testing.T) {
+	p := &Pizza{Ingredients: make([]Ingredient, 1)}
+	err := p.Validate()
+	errs, ok := err.(ValidationErrors)
+	if !ok || !hasCode(errs, ErrDoughRequired) {
+		t.Errorf("Validate() = %v, want %s", err, ErrDoughRequired)
+	}
+}
+
+func TestIngredientValidate(t *testing.T) {
+	if err := (&Ingredient{Name: "basil"}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+
+	err := (&Ingredient{Name: ""}).Validate()
+	errs, ok := err.(ValidationErrors)
+	if !ok || !hasCode(errs, ErrIngredientNameEmpty) {
+		t.Errorf("Validate() = %v, want %s", err, ErrIngredientNameEmpty)
+	}
+}
+
+func TestValidationErrorsProblem(t *testing.T) {
+	errs := ValidationErrors{{Field: "username", Code: ErrUsernameEmpty, Message: "username field is empty"}}
+	p := errs.Problem()
+	if p.Status != 400 || len(p.Errors) != 1 {
+		t.Errorf("Problem() = %+v, want Status=400 and one error", p)
+	}
+}